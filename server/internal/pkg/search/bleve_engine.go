@@ -0,0 +1,142 @@
+package search
+
+import (
+	"log"
+	"log/slog"
+
+	"github.com/blevesearch/bleve/v2"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/index/scorch"
+)
+
+// cjkAnalyzer 中文内容分词使用的 2-gram 分析器，比默认的 "en" 分析器更适合
+// 中文 BBS 的标题、正文内容
+const cjkAnalyzer = "cjk"
+
+// keywordAnalyzer 标签字段使用的分析器，将整个字符串当作一个 term，
+// 保证标签可以精确匹配而不会被二次分词
+const keywordAnalyzer = "keyword"
+
+// bleveEngine 基于本地 bleve 索引文件的 Engine 实现
+type bleveEngine struct {
+	index bleve.Index
+}
+
+func newBleveEngine(cfg BleveConfig) (Engine, error) {
+	idx, err := bleve.Open(cfg.IndexPath)
+	if err != nil {
+		idx, err = bleve.NewUsing(cfg.IndexPath, buildIndexMapping(), scorch.Name, scorch.Name, nil)
+		if err != nil {
+			log.Fatalf("创建索引失败: %v", err)
+		}
+	}
+	return &bleveEngine{index: idx}, nil
+}
+
+// buildIndexMapping 构建 topic 文档的索引映射
+func buildIndexMapping() *bleve.IndexMapping {
+	textField := bleve.NewTextFieldMapping()
+	textField.Store = true
+	textField.Index = true
+	textField.IncludeTermVectors = true
+	textField.Analyzer = cjkAnalyzer
+
+	// tags 按关键词索引，每个标签整体作为一个 term，便于精确的标签过滤
+	tagField := bleve.NewTextFieldMapping()
+	tagField.Store = true
+	tagField.Index = true
+	tagField.Analyzer = keywordAnalyzer
+
+	numField := bleve.NewNumericFieldMapping()
+	numField.DocValues = true
+	numField.Store = true
+	numField.Index = true
+
+	boolField := bleve.NewBooleanFieldMapping()
+	boolField.DocValues = true
+	boolField.Store = true
+	boolField.Index = true
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping.AddFieldMappingsAt("id", numField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("nodeId", numField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("userId", numField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("nickname", textField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("title", textField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("content", textField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("tags", tagField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("recommend", boolField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("status", numField)
+	indexMapping.DefaultMapping.AddFieldMappingsAt("createTime", numField)
+
+	return indexMapping
+}
+
+func (e *bleveEngine) Index(id string, data interface{}) error {
+	return e.index.Index(id, data)
+}
+
+func (e *bleveEngine) Delete(id string) error {
+	return e.index.Delete(id)
+}
+
+func (e *bleveEngine) Search(req *bleve.SearchRequest) (*bleve.SearchResult, error) {
+	result, err := e.index.Search(req)
+	if err != nil {
+		slog.Error("搜索失败:", slog.Any("err", err))
+		return nil, err
+	}
+	return result, nil
+}
+
+func (e *bleveEngine) Bulk(docs map[string]interface{}) error {
+	batch := e.index.NewBatch()
+	for id, data := range docs {
+		if err := batch.Index(id, data); err != nil {
+			return err
+		}
+	}
+	return e.index.Batch(batch)
+}
+
+// DocIDs 用底层 index.IndexReader 的 DocIDReaderAll 遍历索引里的全部文档 id，
+// 比 Search+MatchAllQuery 更直接，不需要把文档内容也读出来
+func (e *bleveEngine) DocIDs() ([]string, error) {
+	idx, _, err := e.index.Advanced()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := idx.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	docIdReader, err := reader.DocIDReaderAll()
+	if err != nil {
+		return nil, err
+	}
+	defer docIdReader.Close()
+
+	var ids []string
+	for {
+		internalId, err := docIdReader.Next()
+		if err != nil {
+			return nil, err
+		}
+		if internalId == nil {
+			break
+		}
+		externalId, err := reader.ExternalID(internalId)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, externalId)
+	}
+	return ids, nil
+}
+
+func (e *bleveEngine) DocCount() (uint64, error) {
+	return e.index.DocCount()
+}
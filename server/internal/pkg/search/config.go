@@ -0,0 +1,54 @@
+package search
+
+import "time"
+
+// Driver 搜索引擎驱动类型
+type Driver string
+
+const (
+	DriverBleve         Driver = "bleve"
+	DriverElasticsearch Driver = "elasticsearch"
+)
+
+// Config 搜索模块配置，对应配置文件中的 search 配置项
+type Config struct {
+	// Driver 引擎驱动，bleve|elasticsearch，默认为 bleve
+	Driver Driver `json:"driver" yaml:"driver"`
+
+	// Bleve 本地 bleve 索引相关配置
+	Bleve BleveConfig `json:"bleve" yaml:"bleve"`
+
+	// Elasticsearch 连接相关配置
+	Elasticsearch ElasticsearchConfig `json:"elasticsearch" yaml:"elasticsearch"`
+}
+
+// BleveConfig bleve 引擎配置
+type BleveConfig struct {
+	// IndexPath 索引文件存储路径
+	IndexPath string `json:"indexPath" yaml:"indexPath"`
+}
+
+// ElasticsearchConfig elasticsearch 引擎配置
+type ElasticsearchConfig struct {
+	// URLs es 节点地址，多个地址用于客户端负载均衡
+	URLs []string `json:"urls" yaml:"urls"`
+	// Username basic auth 用户名
+	Username string `json:"username" yaml:"username"`
+	// Password basic auth 密码
+	Password string `json:"password" yaml:"password"`
+	// Index 索引名称
+	Index string `json:"index" yaml:"index"`
+	// Sniff 是否开启节点嗅探，单机部署或经过代理时建议关闭
+	Sniff bool `json:"sniff" yaml:"sniff"`
+	// HealthcheckInterval 健康检查间隔，<=0 时使用 elastic 客户端默认值
+	HealthcheckInterval time.Duration `json:"healthcheckInterval" yaml:"healthcheckInterval"`
+	// Gzip 是否开启请求压缩
+	Gzip bool `json:"gzip" yaml:"gzip"`
+}
+
+func (c *Config) driverOrDefault() Driver {
+	if c.Driver == "" {
+		return DriverBleve
+	}
+	return c.Driver
+}
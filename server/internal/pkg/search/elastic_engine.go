@@ -0,0 +1,366 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/olivere/elastic/v7"
+)
+
+// elasticEngine 基于 elasticsearch 的 Engine 实现，供集群化部署使用。
+// 对外仍然复用 bleve 的 SearchRequest/SearchResult 作为统一协议，
+// 这里负责把 bleve 查询对象翻译成 ES 查询，再把 ES 响应翻译回 bleve 的结果结构。
+type elasticEngine struct {
+	client *elastic.Client
+	index  string
+}
+
+func newElasticEngine(cfg ElasticsearchConfig) (Engine, error) {
+	opts := []elastic.ClientOptionFunc{
+		elastic.SetURL(cfg.URLs...),
+		elastic.SetSniff(cfg.Sniff),
+		elastic.SetGzip(cfg.Gzip),
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		opts = append(opts, elastic.SetBasicAuth(cfg.Username, cfg.Password))
+	}
+	if cfg.HealthcheckInterval > 0 {
+		opts = append(opts, elastic.SetHealthcheckInterval(cfg.HealthcheckInterval))
+	}
+
+	client, err := elastic.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("创建 elasticsearch 客户端失败: %w", err)
+	}
+
+	e := &elasticEngine{client: client, index: cfg.Index}
+	if err := e.ensureIndex(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *elasticEngine) ensureIndex() error {
+	ctx := context.Background()
+	exists, err := e.client.IndexExists(e.index).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("检查 elasticsearch 索引失败: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	_, err = e.client.CreateIndex(e.index).BodyJson(indexMapping()).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("创建 elasticsearch 索引失败: %w", err)
+	}
+	return nil
+}
+
+// indexMapping 构建 topic 文档的 ES 索引映射，字段语义对齐 bleve_engine.go 的
+// buildIndexMapping。不显式建 mapping 的话，tags 这类字符串数组会落到 ES 默认的
+// 动态 text 映射上，fielddata 默认关闭，SearchWithFacets 对 tags 做 terms 聚合
+// 会直接报错而不是退化，所以这里把 tags 映射成 keyword 保证可以被聚合和精确过滤
+func indexMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"id":         map[string]interface{}{"type": "long"},
+				"nodeId":     map[string]interface{}{"type": "long"},
+				"userId":     map[string]interface{}{"type": "long"},
+				"nickname":   map[string]interface{}{"type": "text"},
+				"title":      map[string]interface{}{"type": "text"},
+				"content":    map[string]interface{}{"type": "text"},
+				"tags":       map[string]interface{}{"type": "keyword"},
+				"recommend":  map[string]interface{}{"type": "boolean"},
+				"status":     map[string]interface{}{"type": "long"},
+				"createTime": map[string]interface{}{"type": "long"},
+			},
+		},
+	}
+}
+
+func (e *elasticEngine) Index(id string, data interface{}) error {
+	_, err := e.client.Index().Index(e.index).Id(id).BodyJson(data).Do(context.Background())
+	if err != nil {
+		slog.Error("elasticsearch 索引失败：", slog.Any("err", err))
+		return err
+	}
+	return nil
+}
+
+func (e *elasticEngine) Delete(id string) error {
+	_, err := e.client.Delete().Index(e.index).Id(id).Do(context.Background())
+	if err != nil && !elastic.IsNotFound(err) {
+		slog.Error("elasticsearch 删除失败：", slog.Any("err", err))
+		return err
+	}
+	return nil
+}
+
+// Bulk 批量索引文档。BulkService.Do 只在传输层/HTTP 层失败时才返回 error，
+// 单个文档写入失败（如 mapping 冲突）体现在 resp.Errors 和各个
+// BulkResponseItem 的 Error 字段里，不检查的话这些文档会悄悄漏索引
+func (e *elasticEngine) Bulk(docs map[string]interface{}) error {
+	bulk := e.client.Bulk().Index(e.index)
+	for id, data := range docs {
+		bulk.Add(elastic.NewBulkIndexRequest().Id(id).Doc(data))
+	}
+	resp, err := bulk.Do(context.Background())
+	if err != nil {
+		slog.Error("elasticsearch 批量索引失败：", slog.Any("err", err))
+		return err
+	}
+	if resp.Errors {
+		failed := resp.Failed()
+		ids := make([]string, 0, len(failed))
+		for _, item := range failed {
+			ids = append(ids, item.Id)
+		}
+		err := fmt.Errorf("elasticsearch 批量索引部分失败，共 %d 个文档: %v", len(ids), ids)
+		slog.Error(err.Error(), slog.Any("ids", ids))
+		return err
+	}
+	return nil
+}
+
+func (e *elasticEngine) DocCount() (uint64, error) {
+	count, err := e.client.Count(e.index).Do(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
+// DocIDs 用 scroll API 遍历索引里的全部文档 id，供一致性巡检按 id 逐个比对
+func (e *elasticEngine) DocIDs() ([]string, error) {
+	ctx := context.Background()
+	scroll := e.client.Scroll(e.index).Size(1000)
+
+	var ids []string
+	for {
+		resp, err := scroll.Do(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range resp.Hits.Hits {
+			ids = append(ids, hit.Id)
+		}
+	}
+	return ids, nil
+}
+
+func (e *elasticEngine) Search(req *bleve.SearchRequest) (*bleve.SearchResult, error) {
+	esQuery := translateQuery(req.Query)
+
+	svc := e.client.Search().Index(e.index).Query(esQuery).From(req.From).Size(req.Size)
+	for _, s := range req.Sort {
+		if sortField, ok := s.(*search.SortField); ok {
+			svc = svc.Sort(sortField.Field, !sortField.Desc)
+		}
+	}
+	if req.Highlight != nil {
+		svc = svc.Highlight(translateHighlight(req.Highlight))
+	}
+	for name, facetReq := range req.Facets {
+		svc = svc.Aggregation(name, translateFacet(facetReq))
+	}
+
+	resp, err := svc.Do(context.Background())
+	if err != nil {
+		slog.Error("elasticsearch 搜索失败：", slog.Any("err", err))
+		return nil, err
+	}
+
+	return toBleveResult(resp, req.Facets), nil
+}
+
+// translateHighlight 把 bleve 的高亮请求翻译为 elasticsearch 的高亮请求
+func translateHighlight(hr *search.HighlightRequest) *elastic.Highlight {
+	highlight := elastic.NewHighlight()
+	for _, field := range hr.Fields {
+		highlight = highlight.Field(field)
+	}
+	return highlight
+}
+
+// translateFacet 把 bleve 的 facet 请求翻译为等价的 elasticsearch 聚合：
+// 带数值区间的翻译为 range 聚合，否则翻译为 terms 聚合
+func translateFacet(fr *search.FacetRequest) elastic.Aggregation {
+	if len(fr.NumericRanges) > 0 {
+		rangeAgg := elastic.NewRangeAggregation().Field(fr.Field)
+		for _, r := range fr.NumericRanges {
+			switch {
+			case r.Min != nil && r.Max != nil:
+				rangeAgg = rangeAgg.AddRangeWithKey(r.Name, *r.Min, *r.Max)
+			case r.Min != nil:
+				rangeAgg = rangeAgg.AddUnboundedFromWithKey(r.Name, *r.Min)
+			case r.Max != nil:
+				rangeAgg = rangeAgg.AddUnboundedToWithKey(r.Name, *r.Max)
+			}
+		}
+		return rangeAgg
+	}
+	return elastic.NewTermsAggregation().Field(fr.Field).Size(fr.Size)
+}
+
+// translateQuery 将 bleve 查询对象翻译为等价的 elasticsearch 查询。
+// 只覆盖 search 包内部构造过的查询类型，其余类型回退为 match_all。
+func translateQuery(q query.Query) elastic.Query {
+	switch t := q.(type) {
+	case *query.MatchQuery:
+		mq := elastic.NewMatchQuery(t.FieldVal, t.Match)
+		if t.BoostVal != nil {
+			mq = mq.Boost(t.BoostVal.Value())
+		}
+		return mq
+	case *query.TermQuery:
+		return elastic.NewTermQuery(t.FieldVal, t.Term)
+	case *query.NumericRangeQuery:
+		// bleve 未指定 Inclusive* 时 min 闭、max 开
+		rq := elastic.NewRangeQuery(t.FieldVal)
+		if t.Min != nil {
+			if t.InclusiveMin != nil && !*t.InclusiveMin {
+				rq = rq.Gt(*t.Min)
+			} else {
+				rq = rq.Gte(*t.Min)
+			}
+		}
+		if t.Max != nil {
+			if t.InclusiveMax != nil && *t.InclusiveMax {
+				rq = rq.Lte(*t.Max)
+			} else {
+				rq = rq.Lt(*t.Max)
+			}
+		}
+		return rq
+	case *query.QueryStringQuery:
+		return elastic.NewQueryStringQuery(t.Query)
+	case *query.BoolFieldQuery:
+		return elastic.NewTermQuery(t.FieldVal, t.Bool)
+	case *query.DisjunctionQuery:
+		boolQuery := elastic.NewBoolQuery().MinimumShouldMatch("1")
+		for _, sub := range t.Disjuncts {
+			boolQuery.Should(translateQuery(sub))
+		}
+		return boolQuery
+	case *query.ConjunctionQuery:
+		boolQuery := elastic.NewBoolQuery()
+		for _, sub := range t.Conjuncts {
+			boolQuery.Must(translateQuery(sub))
+		}
+		return boolQuery
+	case *query.BooleanQuery:
+		boolQuery := elastic.NewBoolQuery()
+		if t.Must != nil {
+			for _, sub := range t.Must.(*query.ConjunctionQuery).Conjuncts {
+				boolQuery.Must(translateQuery(sub))
+			}
+		}
+		if t.Should != nil {
+			for _, sub := range t.Should.(*query.DisjunctionQuery).Disjuncts {
+				boolQuery.Should(translateQuery(sub))
+			}
+		}
+		if t.MustNot != nil {
+			for _, sub := range t.MustNot.(*query.DisjunctionQuery).Disjuncts {
+				boolQuery.MustNot(translateQuery(sub))
+			}
+		}
+		return boolQuery
+	default:
+		return elastic.NewMatchAllQuery()
+	}
+}
+
+// toBleveResult 把 elasticsearch 的响应翻译为 bleve 的 SearchResult，
+// 使上层调用方无需关心底层是哪种引擎
+func toBleveResult(resp *elastic.SearchResult, facetReqs search.FacetsRequest) *bleve.SearchResult {
+	result := &bleve.SearchResult{
+		Total: uint64(resp.TotalHits()),
+		Took:  time.Duration(resp.TookInMillis) * time.Millisecond,
+	}
+
+	for _, hit := range resp.Hits.Hits {
+		fields := make(map[string]interface{})
+		if hit.Source != nil {
+			if err := json.Unmarshal(hit.Source, &fields); err != nil {
+				slog.Error("解析 elasticsearch 文档失败：", slog.Any("err", err))
+				continue
+			}
+		}
+		match := &search.DocumentMatch{
+			ID:     hit.Id,
+			Score:  getScore(hit.Score),
+			Fields: fields,
+		}
+		if len(hit.Highlight) > 0 {
+			match.Fragments = search.FieldFragmentMap(hit.Highlight)
+		}
+		result.Hits = append(result.Hits, match)
+	}
+
+	if len(facetReqs) > 0 {
+		result.Facets = facetsFromAggregations(resp, facetReqs)
+	}
+
+	return result
+}
+
+// facetsFromAggregations 把 elasticsearch 的聚合结果翻译为 bleve 的 FacetResult，
+// 这样无论底层是哪种引擎，facets.go 里的 facetsFromResult 都可以直接复用
+func facetsFromAggregations(resp *elastic.SearchResult, facetReqs search.FacetsRequest) search.FacetResults {
+	out := make(search.FacetResults, len(facetReqs))
+
+	for name, fr := range facetReqs {
+		result := &search.FacetResult{Field: fr.Field}
+
+		if len(fr.NumericRanges) > 0 {
+			if agg, found := resp.Aggregations.Range(name); found {
+				for _, bucket := range agg.Buckets {
+					result.NumericRanges = append(result.NumericRanges, &search.NumericRangeFacet{
+						Name:  bucket.Key,
+						Count: int(bucket.DocCount),
+					})
+				}
+			}
+		} else if agg, found := resp.Aggregations.Terms(name); found {
+			terms := make(search.TermFacets, 0, len(agg.Buckets))
+			for _, bucket := range agg.Buckets {
+				// keyword 字段（tags）的 key 是字符串，long 字段（nodeId）的 key 是数字
+				var term string
+				switch key := bucket.Key.(type) {
+				case string:
+					term = key
+				case float64:
+					term = strconv.FormatFloat(key, 'f', -1, 64)
+				default:
+					continue
+				}
+				terms = terms.Add(&search.TermFacet{Term: term, Count: int(bucket.DocCount)})
+			}
+			result.Terms = &terms
+		}
+
+		out[name] = result
+	}
+
+	return out
+}
+
+func getScore(score *float64) float64 {
+	if score == nil {
+		return 0
+	}
+	return *score
+}
@@ -0,0 +1,275 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/olivere/elastic/v7"
+)
+
+// mustSource renders an elastic.Query/Aggregation/Highlight down to its JSON
+// request body so tests can assert on the shape actually sent to ES, instead
+// of reaching into olivere/elastic's unexported fields.
+func mustSource(t *testing.T, src interface{ Source() (interface{}, error) }) map[string]interface{} {
+	t.Helper()
+	raw, err := src.Source()
+	if err != nil {
+		t.Fatalf("Source() error: %v", err)
+	}
+	body, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(body, &m); err != nil {
+		t.Fatalf("expected a JSON object source, got %s", body)
+	}
+	return m
+}
+
+func TestTranslateQuery_Match(t *testing.T) {
+	mq := query.NewMatchQuery("golang")
+	mq.SetField("title")
+	mq.SetBoost(3)
+
+	got := mustSource(t, translateQuery(mq))
+
+	match, ok := got["match"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'match' clause, got %v", got)
+	}
+	field, ok := match["title"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the match clause on field 'title', got %v", match)
+	}
+	if field["query"] != "golang" {
+		t.Errorf("expected query 'golang', got %v", field["query"])
+	}
+	if field["boost"] != 3.0 {
+		t.Errorf("expected boost to be translated to 3, got %v", field["boost"])
+	}
+}
+
+func TestTranslateQuery_TermQuery(t *testing.T) {
+	tq := query.NewTermQuery("go")
+	tq.SetField("tags")
+
+	got := mustSource(t, translateQuery(tq))
+
+	term, ok := got["term"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'term' clause, got %v", got)
+	}
+	if _, ok := term["tags"]; !ok {
+		t.Errorf("expected the term clause on field 'tags', got %v", term)
+	}
+}
+
+func TestTranslateQuery_NumericRange(t *testing.T) {
+	min, max := 10.0, 20.0
+	rq := query.NewNumericRangeQuery(&min, &max)
+	rq.SetField("nodeId")
+
+	got := mustSource(t, translateQuery(rq))
+
+	rangeClause, ok := got["range"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'range' clause, got %v", got)
+	}
+	field, ok := rangeClause["nodeId"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the range clause on field 'nodeId', got %v", rangeClause)
+	}
+	if field["gte"] != min {
+		t.Errorf("expected gte=%v, got %v", min, field["gte"])
+	}
+	if field["lte"] != max {
+		t.Errorf("expected lte=%v, got %v", max, field["lte"])
+	}
+}
+
+func TestTranslateQuery_BoolFieldQuery(t *testing.T) {
+	bq := query.NewBoolFieldQuery(true)
+	bq.SetField("recommend")
+
+	got := mustSource(t, translateQuery(bq))
+
+	term, ok := got["term"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'term' clause, got %v", got)
+	}
+	if term["recommend"] != true {
+		t.Errorf("expected recommend=true, got %v", term["recommend"])
+	}
+}
+
+func TestTranslateQuery_Disjunction(t *testing.T) {
+	a := query.NewTermQuery("go")
+	a.SetField("tags")
+	b := query.NewTermQuery("web")
+	b.SetField("tags")
+
+	got := mustSource(t, translateQuery(query.NewDisjunctionQuery([]query.Query{a, b})))
+
+	boolClause, ok := got["bool"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'bool' clause, got %v", got)
+	}
+	should, ok := boolClause["should"].([]interface{})
+	if !ok || len(should) != 2 {
+		t.Fatalf("expected 2 'should' clauses, got %v", boolClause["should"])
+	}
+	if boolClause["minimum_should_match"] != "1" {
+		t.Errorf("expected minimum_should_match=1, got %v", boolClause["minimum_should_match"])
+	}
+}
+
+func TestTranslateQuery_Conjunction(t *testing.T) {
+	a := query.NewTermQuery("go")
+	a.SetField("tags")
+	b := query.NewTermQuery("web")
+	b.SetField("tags")
+
+	got := mustSource(t, translateQuery(query.NewConjunctionQuery([]query.Query{a, b})))
+
+	boolClause, ok := got["bool"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'bool' clause, got %v", got)
+	}
+	must, ok := boolClause["must"].([]interface{})
+	if !ok || len(must) != 2 {
+		t.Fatalf("expected 2 'must' clauses, got %v", boolClause["must"])
+	}
+}
+
+func TestTranslateQuery_QueryString(t *testing.T) {
+	got := mustSource(t, translateQuery(query.NewQueryStringQuery("title:golang +tags:web")))
+
+	if _, ok := got["query_string"]; !ok {
+		t.Fatalf("expected a 'query_string' clause, got %v", got)
+	}
+}
+
+func TestTranslateQuery_UnknownFallsBackToMatchAll(t *testing.T) {
+	got := mustSource(t, translateQuery(nil))
+
+	if _, ok := got["match_all"]; !ok {
+		t.Fatalf("expected an unhandled query type to fall back to 'match_all', got %v", got)
+	}
+}
+
+func TestTranslateFacet_Terms(t *testing.T) {
+	fr := bleveFacetRequest("tags", 10)
+
+	got := mustSource(t, translateFacet(fr))
+
+	terms, ok := got["terms"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'terms' aggregation, got %v", got)
+	}
+	if terms["field"] != "tags" {
+		t.Errorf("expected field 'tags', got %v", terms["field"])
+	}
+	if terms["size"] != 10.0 {
+		t.Errorf("expected size 10, got %v", terms["size"])
+	}
+}
+
+func TestTranslateFacet_NumericRanges(t *testing.T) {
+	oneDay, now := 100.0, 200.0
+	fr := bleveFacetRequest("createTime", 0)
+	fr.AddNumericRange("1d", &oneDay, &now)
+
+	got := mustSource(t, translateFacet(fr))
+
+	rangeAgg, ok := got["range"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a 'range' aggregation, got %v", got)
+	}
+	if rangeAgg["field"] != "createTime" {
+		t.Errorf("expected field 'createTime', got %v", rangeAgg["field"])
+	}
+	ranges, ok := rangeAgg["ranges"].([]interface{})
+	if !ok || len(ranges) != 1 {
+		t.Fatalf("expected 1 range bucket, got %v", rangeAgg["ranges"])
+	}
+}
+
+func TestTranslateHighlight(t *testing.T) {
+	hr := search.NewHighlight()
+	hr.Fields = []*search.FieldFragmentation{{Field: "title"}, {Field: "content"}}
+
+	got := mustSource(t, translateHighlight(hr))
+
+	fields, ok := got["fields"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected 'fields', got %v", got)
+	}
+	if _, ok := fields["title"]; !ok {
+		t.Errorf("expected highlight on field 'title', got %v", fields)
+	}
+	if _, ok := fields["content"]; !ok {
+		t.Errorf("expected highlight on field 'content', got %v", fields)
+	}
+}
+
+// TestFacetsFromAggregations_Terms pins the translation of an ES terms
+// aggregation response back into bleve's FacetResult shape, which
+// facets.go's facetsFromResult consumes regardless of which engine produced it.
+func TestFacetsFromAggregations_Terms(t *testing.T) {
+	resp := &elastic.SearchResult{
+		Aggregations: elastic.Aggregations{
+			"tags": json.RawMessage(`{"buckets":[{"key":"go","doc_count":5},{"key":"web","doc_count":3}]}`),
+		},
+	}
+	facetReqs := search.FacetsRequest{
+		"tags": &search.FacetRequest{Field: "tags", Size: 10},
+	}
+
+	out := facetsFromAggregations(resp, facetReqs)
+
+	result, ok := out["tags"]
+	if !ok {
+		t.Fatalf("expected a 'tags' facet result, got %v", out)
+	}
+	if result.Terms == nil || len(*result.Terms) != 2 {
+		t.Fatalf("expected 2 term facets, got %v", result.Terms)
+	}
+}
+
+// TestFacetsFromAggregations_Range pins the translation of an ES range
+// aggregation response (used for the createTime 1d/1w/1m/1y range facet).
+func TestFacetsFromAggregations_Range(t *testing.T) {
+	resp := &elastic.SearchResult{
+		Aggregations: elastic.Aggregations{
+			"createTime": json.RawMessage(`{"buckets":[{"key":"1d","doc_count":2,"from":100,"to":200}]}`),
+		},
+	}
+	facetReqs := search.FacetsRequest{
+		"createTime": &search.FacetRequest{
+			Field: "createTime",
+			NumericRanges: []*search.NumericRangeRequest{
+				{Name: "1d"},
+			},
+		},
+	}
+
+	out := facetsFromAggregations(resp, facetReqs)
+
+	result, ok := out["createTime"]
+	if !ok {
+		t.Fatalf("expected a 'createTime' facet result, got %v", out)
+	}
+	if len(result.NumericRanges) != 1 || result.NumericRanges[0].Name != "1d" || result.NumericRanges[0].Count != 2 {
+		t.Fatalf("expected 1 numeric range bucket named '1d' with count 2, got %v", result.NumericRanges)
+	}
+}
+
+// bleveFacetRequest mirrors how facets.go builds bleve facet requests,
+// avoiding a direct dependency on the bleve package constructors inside the
+// elastic-specific tests.
+func bleveFacetRequest(field string, size int) *search.FacetRequest {
+	return &search.FacetRequest{Field: field, Size: size}
+}
@@ -0,0 +1,31 @@
+package search
+
+import "github.com/blevesearch/bleve/v2"
+
+// Engine 搜索引擎抽象，屏蔽底层 bleve、elasticsearch 等具体实现的差异。
+// SearchPage、IndexData、DeleteData 等包级函数均通过该接口操作索引，
+// 具体使用哪种实现由 Config.Driver 决定。
+type Engine interface {
+	// Index 新增或更新一个文档
+	Index(id string, data interface{}) error
+
+	// Delete 删除一个文档
+	Delete(id string) error
+
+	// Search 执行一次查询，复用 bleve 的请求/响应结构作为统一的查询协议，
+	// 非 bleve 实现负责将其翻译为自身的查询语法
+	Search(req *bleve.SearchRequest) (*bleve.SearchResult, error)
+
+	// Bulk 批量索引文档，id -> 文档数据
+	Bulk(docs map[string]interface{}) error
+
+	// DocIDs 返回索引中当前全部文档的 id，供一致性巡检按 id 逐个比对数据库和索引，
+	// 而不是只比较总数
+	DocIDs() ([]string, error)
+
+	// DocCount 返回当前索引中的文档总数
+	DocCount() (uint64, error)
+}
+
+// engine 当前启用的搜索引擎实例，由 Init 根据配置初始化
+var engine Engine
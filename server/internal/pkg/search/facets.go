@@ -0,0 +1,111 @@
+package search
+
+import (
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/mlogclub/simple/sqls"
+)
+
+const (
+	facetTags = "tags"
+	facetNode = "nodeId"
+	facetDate = "createTime"
+)
+
+// FacetTerm 词条聚合中的单个词条及其命中数
+type FacetTerm struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// FacetRange 区间聚合中的单个区间及其命中数，用于 createTime 的 1d/1w/1m/1y 分桶
+type FacetRange struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// FacetResult 单个 facet 的聚合结果，Terms 与 Ranges 互斥，由字段类型决定
+type FacetResult struct {
+	Field  string       `json:"field"`
+	Total  int          `json:"total"`
+	Other  int          `json:"other"`
+	Terms  []FacetTerm  `json:"terms,omitempty"`
+	Ranges []FacetRange `json:"ranges,omitempty"`
+}
+
+// SearchResult SearchWithFacets 的返回结果，在命中列表、分页之外附带聚合统计，
+// 供前端渲染节点筛选、标签云、发布时间筛选等分面侧栏
+type SearchResult struct {
+	Docs   []TopicDocument        `json:"docs"`
+	Paging *sqls.Paging           `json:"paging"`
+	Facets map[string]FacetResult `json:"facets"`
+}
+
+// SearchWithFacets 在 SearchPage 的基础上同时返回 tags、nodeId 的词条聚合，
+// 以及 createTime 的时间区间聚合（1d/1w/1m/1y），供前端渲染分面筛选侧栏
+func SearchWithFacets(query SearchQuery, page, limit int) (*SearchResult, error) {
+	searchRequest := buildSearchRequest(query, page, limit)
+	addFacets(searchRequest)
+
+	results, err := engine.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []TopicDocument
+	for _, hit := range results.Hits {
+		docs = append(docs, docFromHit(hit))
+	}
+
+	return &SearchResult{
+		Docs:   docs,
+		Paging: &sqls.Paging{Page: page, Limit: limit},
+		Facets: facetsFromResult(results),
+	}, nil
+}
+
+// addFacets 给搜索请求挂上 tags、nodeId 的词条聚合，以及 createTime 的时间分桶聚合。
+// createTime 以毫秒时间戳存成数值字段，因此分桶用 AddNumericRange 而非 AddDateTimeRange
+func addFacets(req *bleve.SearchRequest) {
+	tagsFacet := bleve.NewFacetRequest("tags", 10)
+	req.AddFacet(facetTags, tagsFacet)
+
+	nodeFacet := bleve.NewFacetRequest("nodeId", 20)
+	req.AddFacet(facetNode, nodeFacet)
+
+	now := float64(time.Now().UnixMilli())
+	oneDay := now - 24*3600*1000
+	oneWeek := now - 7*24*3600*1000
+	oneMonth := now - 30*24*3600*1000
+	oneYear := now - 365*24*3600*1000
+
+	dateFacet := bleve.NewFacetRequest("createTime", 0)
+	dateFacet.AddNumericRange("1d", &oneDay, &now)
+	dateFacet.AddNumericRange("1w", &oneWeek, &now)
+	dateFacet.AddNumericRange("1m", &oneMonth, &now)
+	dateFacet.AddNumericRange("1y", &oneYear, &now)
+	req.AddFacet(facetDate, dateFacet)
+}
+
+// facetsFromResult 把 bleve 的聚合结果翻译为对外的 FacetResult
+func facetsFromResult(results *bleve.SearchResult) map[string]FacetResult {
+	out := make(map[string]FacetResult, len(results.Facets))
+
+	for name, facet := range results.Facets {
+		fr := FacetResult{Field: facet.Field, Total: facet.Total, Other: facet.Other}
+
+		if facet.Terms != nil {
+			for _, t := range *facet.Terms {
+				fr.Terms = append(fr.Terms, FacetTerm{Term: t.Term, Count: t.Count})
+			}
+		}
+		for _, r := range facet.NumericRanges {
+			fr.Ranges = append(fr.Ranges, FacetRange{Name: r.Name, Count: r.Count})
+		}
+
+		out[name] = fr
+	}
+
+	return out
+}
@@ -0,0 +1,95 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SearchHandler 处理 GET /search，把查询参数翻译成 SearchQuery 并调用
+// SearchWithFacets，返回命中列表、分页信息，以及 tags、nodeId、createTime
+// 的聚合结果，供前端渲染分面筛选侧栏
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	page, limit := parsePaging(params)
+
+	query := SearchQuery{
+		Keyword:   params.Get("keyword"),
+		NodeId:    parseInt64(params.Get("nodeId")),
+		UserId:    parseInt64(params.Get("userId")),
+		Tags:      parseTags(params.Get("tags")),
+		Recommend: parseBoolPtr(params.Get("recommend")),
+		Status:    parseInts(params.Get("status")),
+		TimeRange: int(parseInt64(params.Get("timeRange"))),
+		SortBy:    SortBy(params.Get("sortBy")),
+	}
+
+	result, err := SearchWithFacets(query, page, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, result)
+}
+
+func parsePaging(params url.Values) (page, limit int) {
+	page, limit = 1, 20
+
+	if n, err := strconv.Atoi(params.Get("page")); err == nil && n > 0 {
+		page = n
+	}
+	if n, err := strconv.Atoi(params.Get("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	return
+}
+
+func parseInt64(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+func parseInts(s string) []int {
+	if s == "" {
+		return nil
+	}
+	var ints []int
+	for _, part := range strings.Split(s, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			ints = append(ints, n)
+		}
+	}
+	return ints
+}
+
+func parseTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func parseBoolPtr(s string) *bool {
+	if s == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil
+	}
+	return &b
+}
+
+func writeJson(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,61 @@
+package indexer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ReindexHandler 处理 POST /admin/search/reindex，不带 since 参数时做全量重建，
+// 带 since（unix 秒）时只回填该时间之后更新过的话题和评论
+func ReindexHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+	if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+		sinceUnix, parseErr := strconv.ParseInt(sinceParam, 10, 64)
+		if parseErr != nil {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+		err = Reindex(ctx, time.Unix(sinceUnix, 0))
+	} else {
+		err = ReindexAll(ctx)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeStats(w)
+}
+
+// StatsHandler 处理 GET /admin/search/stats，返回索引进度与文档总数
+func StatsHandler(w http.ResponseWriter, r *http.Request) {
+	writeStats(w)
+}
+
+// VerifyHandler 处理 POST /admin/search/verify，触发一次数据库与索引的一致性巡检
+func VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := Verify(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, report)
+}
+
+func writeStats(w http.ResponseWriter) {
+	stats, err := GetStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, stats)
+}
+
+func writeJson(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,526 @@
+// Package indexer 负责把话题、评论数据同步进搜索索引：冷启动时的全量/增量回填、
+// 写路径上的批量刷库，以及数据库与索引之间的一致性巡检。
+package indexer
+
+import (
+	"bbs-go/internal/models"
+	"bbs-go/internal/pkg/search"
+	"bbs-go/internal/repositories"
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mlogclub/simple/sqls"
+)
+
+const (
+	// batchSize 每批写入索引的文档数
+	batchSize = 500
+	// eventBufferSize 事件通道容量，超出后新事件会被丢弃并记录告警日志
+	eventBufferSize = 4096
+	// debounceWindow 合并事件的时间窗口，同一话题在窗口内多次变更只刷一次
+	debounceWindow = 2 * time.Second
+	// reindexWorkers 回填时并发写入索引的 worker 数量。按 id 游标翻页本身必须
+	// 串行（下一页的起点依赖上一页最后一个 id），但把页转换成文档、写入索引
+	// 是纯 IO，可以在翻页的同时用有界 worker pool 并发执行，提升回填吞吐
+	reindexWorkers = 4
+)
+
+// EventType 索引事件类型
+type EventType int
+
+const (
+	EventUpsert EventType = iota // 新增或编辑
+	EventDelete
+)
+
+// EntityType 事件关联的实体类型。话题和评论共用同一条事件通道、同一套
+// debounce/批量写入逻辑，只在落库读取和文档转换时分别处理
+type EntityType int
+
+const (
+	EntityTopic EntityType = iota
+	EntityComment
+)
+
+// Event 一次话题/评论变更事件，由业务层在写库成功后投递
+type Event struct {
+	Entity EntityType
+	Type   EventType
+	Id     int64
+}
+
+// pendingKey 合并同一实体在 debounceWindow 内的多次变更，Entity+Id 联合
+// 去重，避免话题和评论各自的自增 id 相互覆盖
+type pendingKey struct {
+	Entity EntityType
+	Id     int64
+}
+
+var (
+	events = make(chan Event, eventBufferSize)
+
+	lastIndexedTopicId   int64
+	lastIndexedCommentId int64
+	indexedCount         int64
+	batchCount           int64
+
+	startOnce sync.Once
+)
+
+// Publish 投递一次索引事件，非阻塞。事件通道由 Start 启动的后台协程消费并合并，
+// 调用方（话题/评论的写路径）不会因为索引而被阻塞
+func Publish(evt Event) {
+	select {
+	case events <- evt:
+	default:
+		slog.Warn("索引事件通道已满，丢弃事件", slog.Any("event", evt))
+	}
+}
+
+// Start 启动后台消费协程，按 debounceWindow 合并事件后批量写入索引，
+// ctx 取消时协程退出
+func Start(ctx context.Context) {
+	startOnce.Do(func() {
+		go consume(ctx)
+	})
+}
+
+func consume(ctx context.Context) {
+	ticker := time.NewTicker(debounceWindow)
+	defer ticker.Stop()
+
+	pending := make(map[pendingKey]EventType)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-events:
+			pending[pendingKey{evt.Entity, evt.Id}] = evt.Type
+		case <-ticker.C:
+			if len(pending) == 0 {
+				continue
+			}
+			flush(pending)
+			pending = make(map[pendingKey]EventType)
+		}
+	}
+}
+
+// isIndexable 判断一个话题是否应该出现在搜索索引里。软删除的话题在数据库中
+// 仍然存在（flush/reindex 都能查到），但必须从索引里摘除而不是当普通数据写回去
+func isIndexable(topic *models.Topic) bool {
+	return topic != nil && topic.Status != models.TopicStatusDeleted
+}
+
+// isCommentIndexable 评论版的 isIndexable，规则相同：软删除的评论不出现在索引里
+func isCommentIndexable(comment *models.Comment) bool {
+	return comment != nil && comment.Status != models.CommentStatusDeleted
+}
+
+func flush(pending map[pendingKey]EventType) {
+	docs := make(map[string]interface{})
+	var deletes []string
+
+	for key, evtType := range pending {
+		did := docId(key.Entity, key.Id)
+		if evtType == EventDelete {
+			deletes = append(deletes, did)
+			continue
+		}
+
+		switch key.Entity {
+		case EntityComment:
+			comment := repositories.CommentRepository.Get(sqls.DB(), key.Id)
+			if !isCommentIndexable(comment) {
+				deletes = append(deletes, did)
+				continue
+			}
+			docs[did] = search.NewCommentDoc(comment)
+		default:
+			topic := repositories.TopicRepository.Get(sqls.DB(), key.Id)
+			if !isIndexable(topic) {
+				deletes = append(deletes, did)
+				continue
+			}
+			docs[did] = search.NewTopicDoc(topic)
+		}
+	}
+
+	if len(docs) > 0 {
+		if err := search.Bulk(docs); err != nil {
+			slog.Error("增量批量索引失败：", slog.Any("err", err))
+		} else {
+			atomic.AddInt64(&indexedCount, int64(len(docs)))
+			atomic.AddInt64(&batchCount, 1)
+		}
+	}
+
+	for _, did := range deletes {
+		if err := search.DeleteData(did); err != nil {
+			slog.Error("增量删除索引失败：", slog.Any("err", err))
+		}
+	}
+}
+
+// ReindexAll 全量重建：按 id 升序分批回填所有话题和评论，用于冷启动（新部署后索引为空）
+func ReindexAll(ctx context.Context) error {
+	return reindex(ctx, nil)
+}
+
+// Reindex 增量重建：只回填 since 之后更新过的话题和评论，用于修复已知时间段内的漏索引
+func Reindex(ctx context.Context, since time.Time) error {
+	return reindex(ctx, &since)
+}
+
+func reindex(ctx context.Context, since *time.Time) error {
+	if err := reindexTopics(ctx, since); err != nil {
+		return err
+	}
+	return reindexComments(ctx, since)
+}
+
+// reindexPage 回填流程里已经转换好的一页数据：待写入的文档和待删除的 id
+type reindexPage struct {
+	docs    map[string]interface{}
+	deletes []string
+}
+
+// pageFetcher 取游标之后的下一页数据，返回转换好的 reindexPage、下一页的起始
+// 游标，以及 noMore（没有更多数据可读）
+type pageFetcher func(afterId int64) (page reindexPage, nextAfterId int64, noMore bool)
+
+func reindexTopics(ctx context.Context, since *time.Time) error {
+	return runReindexStream(ctx, &lastIndexedTopicId, func(afterId int64) (reindexPage, int64, bool) {
+		cnd := sqls.NewCnd().Where("id > ?", afterId).Asc("id").Limit(batchSize)
+		if since != nil {
+			cnd = cnd.Where("update_time >= ?", since.UnixMilli())
+		}
+
+		topics := repositories.TopicRepository.Find(sqls.DB(), cnd)
+		if len(topics) == 0 {
+			return reindexPage{}, afterId, true
+		}
+
+		page := reindexPage{docs: make(map[string]interface{}, len(topics))}
+		for _, topic := range topics {
+			if isIndexable(topic) {
+				page.docs[docId(EntityTopic, topic.Id)] = search.NewTopicDoc(topic)
+			} else {
+				// 软删除的话题：回填时不能再写回索引，反而要把历史上残留的文档摘除
+				page.deletes = append(page.deletes, docId(EntityTopic, topic.Id))
+			}
+		}
+		return page, topics[len(topics)-1].Id, false
+	})
+}
+
+// reindexComments 与 reindexTopics 逻辑对称，回填评论
+func reindexComments(ctx context.Context, since *time.Time) error {
+	return runReindexStream(ctx, &lastIndexedCommentId, func(afterId int64) (reindexPage, int64, bool) {
+		cnd := sqls.NewCnd().Where("id > ?", afterId).Asc("id").Limit(batchSize)
+		if since != nil {
+			cnd = cnd.Where("update_time >= ?", since.UnixMilli())
+		}
+
+		comments := repositories.CommentRepository.Find(sqls.DB(), cnd)
+		if len(comments) == 0 {
+			return reindexPage{}, afterId, true
+		}
+
+		page := reindexPage{docs: make(map[string]interface{}, len(comments))}
+		for _, comment := range comments {
+			if isCommentIndexable(comment) {
+				page.docs[docId(EntityComment, comment.Id)] = search.NewCommentDoc(comment)
+			} else {
+				page.deletes = append(page.deletes, docId(EntityComment, comment.Id))
+			}
+		}
+		return page, comments[len(comments)-1].Id, false
+	})
+}
+
+// runReindexStream 翻页必须串行（下一页的起点依赖上一页最后一个 id），这里用
+// 一个 goroutine 顺序翻页产出 reindexPage，再用 reindexWorkers 个 worker
+// 并发把页写入索引，翻页和写入因此可以互相重叠
+func runReindexStream(ctx context.Context, lastIndexedId *int64, fetch pageFetcher) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make(chan reindexPage, reindexWorkers)
+	fetchErrCh := make(chan error, 1)
+
+	go func() {
+		defer close(pages)
+		var afterId int64
+		for {
+			select {
+			case <-streamCtx.Done():
+				fetchErrCh <- streamCtx.Err()
+				return
+			default:
+			}
+
+			page, nextAfterId, noMore := fetch(afterId)
+			if noMore {
+				fetchErrCh <- nil
+				return
+			}
+
+			select {
+			case pages <- page:
+			case <-streamCtx.Done():
+				fetchErrCh <- streamCtx.Err()
+				return
+			}
+
+			afterId = nextAfterId
+			atomic.StoreInt64(lastIndexedId, afterId)
+		}
+	}()
+
+	if err := drainReindexPages(pages, cancel); err != nil {
+		return err
+	}
+	return <-fetchErrCh
+}
+
+// drainReindexPages 用有界 worker pool 并发消费 pages，任意一个写入失败就
+// cancel 掉 streamCtx 让翻页协程尽快停下来，而不是继续翻页翻到底
+func drainReindexPages(pages <-chan reindexPage, cancel context.CancelFunc) error {
+	var wg sync.WaitGroup
+	errOnce := make(chan error, 1)
+
+	for i := 0; i < reindexWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				if err := writeReindexPage(page); err != nil {
+					select {
+					case errOnce <- err:
+						cancel()
+					default:
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errOnce:
+		return err
+	default:
+		return nil
+	}
+}
+
+func writeReindexPage(page reindexPage) error {
+	if len(page.docs) > 0 {
+		if err := search.Bulk(page.docs); err != nil {
+			return err
+		}
+	}
+	for _, did := range page.deletes {
+		if err := search.DeleteData(did); err != nil {
+			return err
+		}
+	}
+
+	atomic.AddInt64(&indexedCount, int64(len(page.docs)+len(page.deletes)))
+	atomic.AddInt64(&batchCount, 1)
+	return nil
+}
+
+// VerifyReport 一次一致性巡检的结果
+type VerifyReport struct {
+	DbCount    int64 `json:"dbCount"`
+	IndexCount int64 `json:"indexCount"`
+	// Missing 数据库有但索引没有的文档数，巡检会把这些文档重新写入索引
+	Missing int64 `json:"missing"`
+	// Stale 索引有但数据库没有的文档数（例如硬删除的行），巡检会把这些文档从索引摘除
+	Stale int64 `json:"stale"`
+	// Repaired 为 true 表示发现了漂移并完成了修复
+	Repaired bool `json:"repaired"`
+}
+
+// Verify 逐个比对数据库和索引的文档 id，而不是只比较总数：总数相等不代表没有
+// 漂移（可能一边漏了一条、另一边多了一条不相干的），总数不等也不代表该全量
+// 重建（可能只是某几条硬删除的行残留在索引里）。比对结果分成 missing（数据库
+// 有、索引没有，重新写入）和 stale（索引有、数据库没有，从索引摘除）两类，
+// 只修复这些具体的 id
+func Verify(ctx context.Context) (VerifyReport, error) {
+	dbIds, err := collectDbDocIds()
+	if err != nil {
+		return VerifyReport{}, err
+	}
+
+	idxIds, err := search.DocIDs()
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	idxIdSet := make(map[string]bool, len(idxIds))
+	for _, did := range idxIds {
+		idxIdSet[did] = true
+	}
+
+	var missing, stale []string
+	for did := range dbIds {
+		if !idxIdSet[did] {
+			missing = append(missing, did)
+		}
+	}
+	for did := range idxIdSet {
+		if !dbIds[did] {
+			stale = append(stale, did)
+		}
+	}
+
+	report := VerifyReport{
+		DbCount:    int64(len(dbIds)),
+		IndexCount: int64(len(idxIdSet)),
+		Missing:    int64(len(missing)),
+		Stale:      int64(len(stale)),
+	}
+	if len(missing) == 0 && len(stale) == 0 {
+		return report, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return report, ctx.Err()
+	default:
+	}
+
+	if err := repairDrift(missing, stale); err != nil {
+		return report, err
+	}
+	report.Repaired = true
+	return report, nil
+}
+
+// collectDbDocIds 按 id 升序分批取出所有未被软删除的话题和评论，返回它们对应的
+// docId 集合，供 Verify 和索引侧的 id 集合做差集比对
+func collectDbDocIds() (map[string]bool, error) {
+	ids := make(map[string]bool)
+
+	var afterId int64
+	for {
+		cnd := sqls.NewCnd().Where("id > ?", afterId).Where("status != ?", models.TopicStatusDeleted).Asc("id").Limit(batchSize)
+		topics := repositories.TopicRepository.Find(sqls.DB(), cnd)
+		if len(topics) == 0 {
+			break
+		}
+		for _, topic := range topics {
+			ids[docId(EntityTopic, topic.Id)] = true
+		}
+		afterId = topics[len(topics)-1].Id
+	}
+
+	afterId = 0
+	for {
+		cnd := sqls.NewCnd().Where("id > ?", afterId).Where("status != ?", models.CommentStatusDeleted).Asc("id").Limit(batchSize)
+		comments := repositories.CommentRepository.Find(sqls.DB(), cnd)
+		if len(comments) == 0 {
+			break
+		}
+		for _, comment := range comments {
+			ids[docId(EntityComment, comment.Id)] = true
+		}
+		afterId = comments[len(comments)-1].Id
+	}
+
+	return ids, nil
+}
+
+// repairDrift 按 id 精确修复 Verify 发现的漂移：missing 重新从数据库取出对应
+// 实体写回索引，stale 直接从索引摘除
+func repairDrift(missing, stale []string) error {
+	docs := make(map[string]interface{}, len(missing))
+	for _, did := range missing {
+		entity, id, ok := parseDocId(did)
+		if !ok {
+			continue
+		}
+
+		switch entity {
+		case EntityComment:
+			comment := repositories.CommentRepository.Get(sqls.DB(), id)
+			if isCommentIndexable(comment) {
+				docs[did] = search.NewCommentDoc(comment)
+			}
+		default:
+			topic := repositories.TopicRepository.Get(sqls.DB(), id)
+			if isIndexable(topic) {
+				docs[did] = search.NewTopicDoc(topic)
+			}
+		}
+	}
+
+	if len(docs) > 0 {
+		if err := search.Bulk(docs); err != nil {
+			return err
+		}
+	}
+	for _, did := range stale {
+		if err := search.DeleteData(did); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDocId 是 docId 的逆操作，把 "topic-123"/"comment-456" 拆回实体类型和数据库 id
+func parseDocId(did string) (EntityType, int64, bool) {
+	if rest, ok := strings.CutPrefix(did, "comment-"); ok {
+		id, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		return EntityComment, id, true
+	}
+	if rest, ok := strings.CutPrefix(did, "topic-"); ok {
+		id, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		return EntityTopic, id, true
+	}
+	return 0, 0, false
+}
+
+// Stats 返回当前的索引进度，供 /admin/search/stats 展示
+type Stats struct {
+	LastIndexedTopicId   int64 `json:"lastIndexedTopicId"`
+	LastIndexedCommentId int64 `json:"lastIndexedCommentId"`
+	IndexedCount         int64 `json:"indexedCount"`
+	BatchCount           int64 `json:"batchCount"`
+	DocCount             int64 `json:"docCount"`
+}
+
+func GetStats() (Stats, error) {
+	docCount, err := search.DocCount()
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		LastIndexedTopicId:   atomic.LoadInt64(&lastIndexedTopicId),
+		LastIndexedCommentId: atomic.LoadInt64(&lastIndexedCommentId),
+		IndexedCount:         atomic.LoadInt64(&indexedCount),
+		BatchCount:           atomic.LoadInt64(&batchCount),
+		DocCount:             int64(docCount),
+	}, nil
+}
+
+// docId 按实体类型给话题/评论文档分配互不冲突的索引 id
+func docId(entity EntityType, id int64) string {
+	if entity == EntityComment {
+		return "comment-" + strconv.FormatInt(id, 10)
+	}
+	return "topic-" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,88 @@
+package indexer
+
+import (
+	"bbs-go/internal/models"
+	"testing"
+)
+
+// TestIsIndexable guards the rule that both flush (hot path) and reindex
+// (backfill/repair path) must agree on: a soft-deleted topic is still a row
+// in the database, but it must never end up written back into the search
+// index.
+func TestIsIndexable(t *testing.T) {
+	tests := []struct {
+		name  string
+		topic *models.Topic
+		want  bool
+	}{
+		{
+			name:  "nil topic",
+			topic: nil,
+			want:  false,
+		},
+		{
+			name:  "deleted topic",
+			topic: &models.Topic{Id: 1, Status: models.TopicStatusDeleted},
+			want:  false,
+		},
+		{
+			name:  "published topic",
+			topic: &models.Topic{Id: 2, Status: models.TopicStatusPublished},
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isIndexable(tt.topic); got != tt.want {
+				t.Errorf("isIndexable(%+v) = %v, want %v", tt.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIsCommentIndexable mirrors TestIsIndexable for comments: a soft-deleted
+// comment is still a row in the database but must never be written back into
+// the search index.
+func TestIsCommentIndexable(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment *models.Comment
+		want    bool
+	}{
+		{
+			name:    "nil comment",
+			comment: nil,
+			want:    false,
+		},
+		{
+			name:    "deleted comment",
+			comment: &models.Comment{Id: 1, Status: models.CommentStatusDeleted},
+			want:    false,
+		},
+		{
+			name:    "published comment",
+			comment: &models.Comment{Id: 2, Status: models.CommentStatusPublished},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCommentIndexable(tt.comment); got != tt.want {
+				t.Errorf("isCommentIndexable(%+v) = %v, want %v", tt.comment, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDocId guards the prefix scheme that keeps topic and comment ids from
+// colliding in a single shared index.
+func TestDocId(t *testing.T) {
+	if got := docId(EntityTopic, 42); got != "topic-42" {
+		t.Errorf("docId(EntityTopic, 42) = %q, want %q", got, "topic-42")
+	}
+	if got := docId(EntityComment, 42); got != "comment-42" {
+		t.Errorf("docId(EntityComment, 42) = %q, want %q", got, "comment-42")
+	}
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+// SortBy 搜索结果排序方式
+type SortBy string
+
+const (
+	// SortByCreateTime 按发布时间倒序，默认排序方式
+	SortByCreateTime SortBy = "createTime"
+	// SortByRelevance 按匹配得分（tf-idf）倒序
+	SortByRelevance SortBy = "relevance"
+)
+
+// SearchQuery 结构化的话题搜索条件，SearchPage 依据该对象构建底层布尔查询，
+// 避免调用方自己拼装 bleve 查询
+type SearchQuery struct {
+	// Keyword 关键字，同时匹配 title、content，title 权重更高
+	Keyword string
+	// NodeId 节点过滤，0 表示不限制
+	NodeId int64
+	// UserId 作者过滤，0 表示不限制
+	UserId int64
+	// Tags 标签过滤，多个标签之间为且的关系
+	Tags []string
+	// Recommend 是否精华，nil 表示不限制
+	Recommend *bool
+	// Status 状态过滤，多个状态之间为或的关系，为空表示不限制
+	Status []int
+	// TimeRange 时间范围：1-一天内 2-一周内 3-一月内 4-一年内，0 表示不限制
+	TimeRange int
+	// SortBy 排序方式，为空时默认按 createTime 倒序
+	SortBy SortBy
+}
+
+// buildQuery 根据 SearchQuery 构建 bleve 布尔查询
+func (q SearchQuery) buildQuery() bleve.Query {
+	boolQuery := bleve.NewBooleanQuery()
+	clauses := 0
+
+	if q.Keyword != "" {
+		titleMatch := bleve.NewMatchQuery(q.Keyword)
+		titleMatch.SetField("title")
+		titleMatch.SetBoost(3)
+
+		contentMatch := bleve.NewMatchQuery(q.Keyword)
+		contentMatch.SetField("content")
+		contentMatch.SetBoost(1)
+
+		boolQuery.AddMust(bleve.NewDisjunctionQuery(titleMatch, contentMatch))
+		clauses++
+	}
+
+	if q.NodeId != 0 {
+		boolQuery.AddMust(exactNumericQuery("nodeId", float64(q.NodeId)))
+		clauses++
+	}
+
+	if q.UserId != 0 {
+		boolQuery.AddMust(exactNumericQuery("userId", float64(q.UserId)))
+		clauses++
+	}
+
+	for _, tag := range q.Tags {
+		tagQuery := bleve.NewTermQuery(tag)
+		tagQuery.SetField("tags")
+		boolQuery.AddMust(tagQuery)
+		clauses++
+	}
+
+	if q.Recommend != nil {
+		recommendQuery := bleve.NewBoolFieldQuery(*q.Recommend)
+		recommendQuery.SetField("recommend")
+		boolQuery.AddMust(recommendQuery)
+		clauses++
+	}
+
+	if len(q.Status) > 0 {
+		statusQuery := bleve.NewDisjunctionQuery()
+		for _, status := range q.Status {
+			statusQuery.AddQuery(exactNumericQuery("status", float64(status)))
+		}
+		boolQuery.AddMust(statusQuery)
+		clauses++
+	}
+
+	if timeQuery := q.buildTimeRangeQuery(); timeQuery != nil {
+		boolQuery.AddMust(timeQuery)
+		clauses++
+	}
+
+	if clauses == 0 {
+		return bleve.NewMatchAllQuery()
+	}
+	return boolQuery
+}
+
+func (q SearchQuery) buildTimeRangeQuery() bleve.Query {
+	if q.TimeRange == 0 {
+		return nil
+	}
+
+	currentTime := time.Now().Unix()
+	var startTime int64
+
+	switch q.TimeRange {
+	case 1: // 一天内
+		startTime = currentTime - 24*3600
+	case 2: // 一周内
+		startTime = currentTime - 7*24*3600
+	case 3: // 一月内
+		startTime = currentTime - 30*24*3600
+	case 4: // 一年内
+		startTime = currentTime - 365*24*3600
+	default:
+		return nil
+	}
+
+	start := float64(startTime * 1000)
+	end := float64(currentTime * 1000)
+
+	timeQuery := bleve.NewNumericRangeQuery(&start, &end)
+	timeQuery.SetField("createTime")
+	return timeQuery
+}
+
+// exactNumericQuery 数值字段的精确匹配，通过 min=max 的范围查询实现
+func exactNumericQuery(field string, value float64) bleve.Query {
+	q := bleve.NewNumericRangeInclusiveQuery(&value, &value, &inclusiveTrue, &inclusiveTrue)
+	q.SetField(field)
+	return q
+}
+
+var inclusiveTrue = true
+
+// buildSearchRequest 根据 SearchQuery 和分页参数构建完整的 bleve 搜索请求
+func buildSearchRequest(q SearchQuery, page, limit int) *bleve.SearchRequest {
+	searchRequest := bleve.NewSearchRequest(q.buildQuery())
+
+	if q.SortBy == SortByRelevance {
+		searchRequest.SortBy([]string{"-_score"})
+	} else {
+		searchRequest.SortBy([]string{"-createTime"})
+	}
+
+	searchRequest.Fields = []string{"id", "nodeId", "userId", "title", "content", "tags", "recommend", "status", "createTime"}
+	searchRequest.Highlight = highlightRequest()
+	searchRequest.From = (page - 1) * limit
+	searchRequest.Size = limit
+
+	return searchRequest
+}
+
+// highlightRequest 构建 title、content 的高亮请求，前端据此在命中片段上
+// 渲染 <mark> 包裹的关键字
+func highlightRequest() *bleve.HighlightRequest {
+	highlight := bleve.NewHighlightWithStyle("html")
+	highlight.AddField("title")
+	highlight.AddField("content")
+	return highlight
+}
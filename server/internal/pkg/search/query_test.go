@@ -0,0 +1,190 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+func mustConjuncts(t *testing.T, q query.Query) []query.Query {
+	t.Helper()
+	boolQuery, ok := q.(*query.BooleanQuery)
+	if !ok {
+		t.Fatalf("expected *query.BooleanQuery, got %T", q)
+	}
+	conjunction, ok := boolQuery.Must.(*query.ConjunctionQuery)
+	if !ok {
+		t.Fatalf("expected Must to be *query.ConjunctionQuery, got %T", boolQuery.Must)
+	}
+	return conjunction.Conjuncts
+}
+
+func TestBuildQuery_Empty(t *testing.T) {
+	q := SearchQuery{}.buildQuery()
+	if _, ok := q.(*query.MatchAllQuery); !ok {
+		t.Fatalf("expected *query.MatchAllQuery for an empty SearchQuery, got %T", q)
+	}
+}
+
+func TestBuildQuery_Keyword(t *testing.T) {
+	conjuncts := mustConjuncts(t, SearchQuery{Keyword: "golang"}.buildQuery())
+	if len(conjuncts) != 1 {
+		t.Fatalf("expected 1 conjunct, got %d", len(conjuncts))
+	}
+
+	disjunction, ok := conjuncts[0].(*query.DisjunctionQuery)
+	if !ok {
+		t.Fatalf("expected *query.DisjunctionQuery, got %T", conjuncts[0])
+	}
+	if len(disjunction.Disjuncts) != 2 {
+		t.Fatalf("expected 2 disjuncts (title, content), got %d", len(disjunction.Disjuncts))
+	}
+
+	wantFields := map[string]bool{"title": false, "content": false}
+	for _, d := range disjunction.Disjuncts {
+		match, ok := d.(*query.MatchQuery)
+		if !ok {
+			t.Fatalf("expected *query.MatchQuery, got %T", d)
+		}
+		if match.Match != "golang" {
+			t.Errorf("expected match text %q, got %q", "golang", match.Match)
+		}
+		if _, known := wantFields[match.FieldVal]; !known {
+			t.Errorf("unexpected field %q", match.FieldVal)
+		}
+		wantFields[match.FieldVal] = true
+	}
+	for field, seen := range wantFields {
+		if !seen {
+			t.Errorf("expected a match query on field %q", field)
+		}
+	}
+}
+
+func TestBuildQuery_NodeIdAndUserId(t *testing.T) {
+	conjuncts := mustConjuncts(t, SearchQuery{NodeId: 10, UserId: 20}.buildQuery())
+	if len(conjuncts) != 2 {
+		t.Fatalf("expected 2 conjuncts, got %d", len(conjuncts))
+	}
+
+	for _, c := range conjuncts {
+		numRange, ok := c.(*query.NumericRangeQuery)
+		if !ok {
+			t.Fatalf("expected *query.NumericRangeQuery, got %T", c)
+		}
+		if numRange.Min == nil || numRange.Max == nil || *numRange.Min != *numRange.Max {
+			t.Fatalf("expected an exact-match range (min == max), got min=%v max=%v", numRange.Min, numRange.Max)
+		}
+		switch numRange.FieldVal {
+		case "nodeId":
+			if *numRange.Min != 10 {
+				t.Errorf("expected nodeId=10, got %v", *numRange.Min)
+			}
+		case "userId":
+			if *numRange.Min != 20 {
+				t.Errorf("expected userId=20, got %v", *numRange.Min)
+			}
+		default:
+			t.Errorf("unexpected field %q", numRange.FieldVal)
+		}
+	}
+}
+
+func TestBuildQuery_Tags(t *testing.T) {
+	conjuncts := mustConjuncts(t, SearchQuery{Tags: []string{"go", "web"}}.buildQuery())
+	if len(conjuncts) != 2 {
+		t.Fatalf("expected 2 conjuncts (one TermQuery per tag), got %d", len(conjuncts))
+	}
+
+	got := map[string]bool{}
+	for _, c := range conjuncts {
+		term, ok := c.(*query.TermQuery)
+		if !ok {
+			t.Fatalf("expected *query.TermQuery, got %T", c)
+		}
+		if term.FieldVal != "tags" {
+			t.Errorf("expected field 'tags', got %q", term.FieldVal)
+		}
+		got[term.Term] = true
+	}
+	if !got["go"] || !got["web"] {
+		t.Errorf("expected terms 'go' and 'web', got %v", got)
+	}
+}
+
+func TestBuildQuery_Recommend(t *testing.T) {
+	yes := true
+	conjuncts := mustConjuncts(t, SearchQuery{Recommend: &yes}.buildQuery())
+	if len(conjuncts) != 1 {
+		t.Fatalf("expected 1 conjunct, got %d", len(conjuncts))
+	}
+	boolField, ok := conjuncts[0].(*query.BoolFieldQuery)
+	if !ok {
+		t.Fatalf("expected *query.BoolFieldQuery, got %T", conjuncts[0])
+	}
+	if boolField.FieldVal != "recommend" {
+		t.Errorf("expected field 'recommend', got %q", boolField.FieldVal)
+	}
+	if !boolField.Bool {
+		t.Errorf("expected bool value true, got %v", boolField.Bool)
+	}
+}
+
+// TestBuildQuery_Status guards against regressing to a plain TermQuery against
+// the numeric "status" field, which bleve indexes as prefix-coded terms and
+// would never match a literal decimal string.
+func TestBuildQuery_Status(t *testing.T) {
+	conjuncts := mustConjuncts(t, SearchQuery{Status: []int{1, 2}}.buildQuery())
+	if len(conjuncts) != 1 {
+		t.Fatalf("expected 1 conjunct, got %d", len(conjuncts))
+	}
+
+	disjunction, ok := conjuncts[0].(*query.DisjunctionQuery)
+	if !ok {
+		t.Fatalf("expected *query.DisjunctionQuery, got %T", conjuncts[0])
+	}
+	if len(disjunction.Disjuncts) != 2 {
+		t.Fatalf("expected 2 disjuncts, got %d", len(disjunction.Disjuncts))
+	}
+
+	wantValues := map[float64]bool{1: false, 2: false}
+	for _, d := range disjunction.Disjuncts {
+		numRange, ok := d.(*query.NumericRangeQuery)
+		if !ok {
+			t.Fatalf("status filter must use NumericRangeQuery (status is a numeric field), got %T", d)
+		}
+		if numRange.FieldVal != "status" {
+			t.Errorf("expected field 'status', got %q", numRange.FieldVal)
+		}
+		if numRange.Min == nil || numRange.Max == nil || *numRange.Min != *numRange.Max {
+			t.Fatalf("expected an exact-match range (min == max), got min=%v max=%v", numRange.Min, numRange.Max)
+		}
+		wantValues[*numRange.Min] = true
+	}
+	for v, seen := range wantValues {
+		if !seen {
+			t.Errorf("expected a status filter for value %v", v)
+		}
+	}
+}
+
+func TestBuildQuery_TimeRange(t *testing.T) {
+	conjuncts := mustConjuncts(t, SearchQuery{TimeRange: 1}.buildQuery())
+	if len(conjuncts) != 1 {
+		t.Fatalf("expected 1 conjunct, got %d", len(conjuncts))
+	}
+	numRange, ok := conjuncts[0].(*query.NumericRangeQuery)
+	if !ok {
+		t.Fatalf("expected *query.NumericRangeQuery, got %T", conjuncts[0])
+	}
+	if numRange.FieldVal != "createTime" {
+		t.Errorf("expected field 'createTime', got %q", numRange.FieldVal)
+	}
+}
+
+func TestBuildQuery_UnknownTimeRangeIsIgnored(t *testing.T) {
+	q := SearchQuery{TimeRange: 99}.buildQuery()
+	if _, ok := q.(*query.MatchAllQuery); !ok {
+		t.Fatalf("expected an unknown TimeRange to be ignored, got %T", q)
+	}
+}
@@ -9,28 +9,25 @@ import (
 	"html"
 	"log"
 	"log/slog"
-	"strings"
-	"time"
 
 	"github.com/blevesearch/bleve/v2"
-	"github.com/blevesearch/bleve/v2/index/scorch"
+	"github.com/blevesearch/bleve/v2/search"
 	"github.com/mlogclub/simple/common/jsons"
 	"github.com/mlogclub/simple/sqls"
 )
 
-var index bleve.Index
-
 type TopicDocument struct {
-	Id         int64    `json:"id"`
-	NodeId     int64    `json:"nodeId"`
-	UserId     int64    `json:"userId"`
-	Nickname   string   `json:"nickname"`
-	Title      string   `json:"title"`
-	Content    string   `json:"content"`
-	Tags       []string `json:"tags"`
-	Recommend  bool     `json:"recommend"`
-	Status     int      `json:"status"`
-	CreateTime int64    `json:"createTime"`
+	Id         int64               `json:"id"`
+	NodeId     int64               `json:"nodeId"`
+	UserId     int64               `json:"userId"`
+	Nickname   string              `json:"nickname"`
+	Title      string              `json:"title"`
+	Content    string              `json:"content"`
+	Tags       []string            `json:"tags"`
+	Recommend  bool                `json:"recommend"`
+	Status     int                 `json:"status"`
+	CreateTime int64               `json:"createTime"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
 }
 
 func (t *TopicDocument) ToStr() string {
@@ -41,44 +38,27 @@ func (t *TopicDocument) ToStr() string {
 	return str
 }
 
-func Init(indexPath string) {
-	var err error
-	index, err = bleve.Open(indexPath)
+// Init 根据配置初始化搜索引擎，driver 为 bleve 时使用本地索引文件，
+// 为 elasticsearch 时连接到外部 ES 集群，集群化部署时可共享索引。
+// 引擎初始化失败直接终止进程，而不是让 engine 保持 nil——SearchPage、
+// IndexData 等包级函数都不会判空，带着一个坏掉的 engine 起服务只会在
+// 第一次搜索请求时 panic，不如启动期就暴露问题
+func Init(cfg Config) {
+	var (
+		eng Engine
+		err error
+	)
+
+	switch cfg.driverOrDefault() {
+	case DriverElasticsearch:
+		eng, err = newElasticEngine(cfg.Elasticsearch)
+	default:
+		eng, err = newBleveEngine(cfg.Bleve)
+	}
 	if err != nil {
-		textField := bleve.NewTextFieldMapping()
-		textField.Store = true
-		textField.Index = true
-		textField.IncludeTermVectors = true
-		textField.Analyzer = "en"
-
-		numField := bleve.NewNumericFieldMapping()
-		numField.DocValues = true
-		numField.Store = true
-		numField.Index = true
-
-		boolField := bleve.NewBooleanFieldMapping()
-		boolField.DocValues = true
-		boolField.Store = true
-		boolField.Index = true
-
-		indexMapping := bleve.NewIndexMapping()
-		indexMapping.DefaultMapping.AddFieldMappingsAt("id", numField)
-		indexMapping.DefaultMapping.AddFieldMappingsAt("nodeId", numField)
-		indexMapping.DefaultMapping.AddFieldMappingsAt("userId", numField)
-		indexMapping.DefaultMapping.AddFieldMappingsAt("nickname", textField)
-		indexMapping.DefaultMapping.AddFieldMappingsAt("title", textField)
-		indexMapping.DefaultMapping.AddFieldMappingsAt("content", textField)
-		// TODO tags
-		indexMapping.DefaultMapping.AddFieldMappingsAt("recommend", boolField)
-		indexMapping.DefaultMapping.AddFieldMappingsAt("status", numField)
-		indexMapping.DefaultMapping.AddFieldMappingsAt("createTime", numField)
-
-		// 使用 scorch 索引类型创建索引
-		index, err = bleve.NewUsing(indexPath, indexMapping, scorch.Name, scorch.Name, nil)
-		if err != nil {
-			log.Fatalf("创建索引失败: %v", err)
-		}
+		log.Fatalf("初始化搜索引擎失败: %v", err)
 	}
+	engine = eng
 }
 
 func NewTopicDoc(topic *models.Topic) *TopicDocument {
@@ -119,6 +99,53 @@ func NewTopicDoc(topic *models.Topic) *TopicDocument {
 	return doc
 }
 
+// CommentDocument 评论索引文档，字段上裁剪自 TopicDocument：评论没有标题、
+// 标签、推荐等话题维度的属性，只保留检索和展示需要的字段
+type CommentDocument struct {
+	Id         int64               `json:"id"`
+	TopicId    int64               `json:"topicId"`
+	UserId     int64               `json:"userId"`
+	Nickname   string              `json:"nickname"`
+	Content    string              `json:"content"`
+	Status     int                 `json:"status"`
+	CreateTime int64               `json:"createTime"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+func (c *CommentDocument) ToStr() string {
+	str, err := jsons.ToStr(c)
+	if err != nil {
+		slog.Error(err.Error(), slog.Any("err", err))
+	}
+	return str
+}
+
+// NewCommentDoc 把评论实体转换为索引文档，供 search/indexer 做增量刷库和回填使用
+func NewCommentDoc(comment *models.Comment) *CommentDocument {
+	if comment == nil {
+		return nil
+	}
+	doc := &CommentDocument{
+		Id:         comment.Id,
+		TopicId:    comment.TopicId,
+		UserId:     comment.UserId,
+		Status:     comment.Status,
+		CreateTime: comment.CreateTime,
+	}
+
+	content := markdown.ToHTML(comment.Content)
+	content = html2.GetHtmlText(content)
+	content = html.EscapeString(content)
+	doc.Content = content
+
+	user := cache.UserCache.Get(comment.UserId)
+	if user != nil {
+		doc.Nickname = user.Nickname
+	}
+
+	return doc
+}
+
 func getTopicTags(topicId int64) []models.Tag {
 	topicTags := repositories.TopicTagRepository.Find(sqls.DB(), sqls.NewCnd().Where("topic_id = ?", topicId))
 
@@ -129,18 +156,16 @@ func getTopicTags(topicId int64) []models.Tag {
 	return cache.TagCache.GetList(tagIds)
 }
 
-// IndexData 索引数据
-func IndexData(did string, id, userId, createTime int64, context string, title string) error {
-	content := markdown.ToHTML(context)
-	content = html2.GetHtmlText(content)
-	content = html.EscapeString(content)
-	return updateData(did, map[string]interface{}{
-		"id":         id,
-		"userId":     userId,
-		"content":    content,
-		"createTime": createTime,
-		"title":      title,
-	})
+// IndexData 索引单个话题。按 topicId 重新查询完整记录，复用 NewTopicDoc 构建
+// 文档，而不是只用调用方传入的几个零散字段——否则这里写入的文档缺少 tags、
+// nodeId、recommend、status，SearchQuery 对这些字段的过滤会静默匹配不到，
+// 即使文档已经通过这条路径写进了索引
+func IndexData(did string, topicId int64) error {
+	topic := repositories.TopicRepository.Get(sqls.DB(), topicId)
+	if topic == nil {
+		return DeleteData(did)
+	}
+	return updateData(did, NewTopicDoc(topic))
 }
 
 // 删除索引
@@ -148,98 +173,115 @@ func DeleteData(did string) error {
 	return updateData(did, nil)
 }
 
-// 分页查询
-func SearchPage(queryText string, timeRange, page, limit int) (docs []TopicDocument, paging *sqls.Paging, err error) {
-	paging = &sqls.Paging{Page: page, Limit: limit}
-	boolQuery := bleve.NewBooleanQuery()
-
-	// 如果queryText不为空，则添加标题匹配子查询
-	if queryText != "" {
-		queryMatch := bleve.NewMatchQuery(queryText)
-		queryMatch.SetField("title")
-		boolQuery.AddMust(queryMatch)
-	}
-
-	// 如果timeRange不为空，则根据时间范围添加时间范围查询
-	if timeRange != 0 {
-		var startTime int64
-		currentTime := time.Now().Unix()
-
-		switch timeRange {
-		case 1: // 一天内
-			startTime = currentTime - 24*3600
-		case 2: // 一周内
-			startTime = currentTime - 7*24*3600
-		case 3: // 一月内
-			startTime = currentTime - 30*24*3600
-		case 4: // 一年内
-			startTime = currentTime - 365*24*3600
-		default:
-			// 其他情况不处理
-		}
-
-		// 添加时间范围查询
-		start := new(float64)
-		end := new(float64)
+// Bulk 批量索引文档，did -> 文档数据，供 search/indexer 做批量回填和增量刷库使用
+func Bulk(docs map[string]interface{}) error {
+	return engine.Bulk(docs)
+}
 
-		*start = float64(startTime * 1000)
-		*end = float64(currentTime * 1000)
+// DocCount 返回索引中的文档总数，供 search/indexer 的索引统计（/admin/search/stats）使用
+func DocCount() (uint64, error) {
+	return engine.DocCount()
+}
 
-		queryTimeRange := bleve.NewNumericRangeQuery(start, end)
-		queryTimeRange.SetField("createTime")
-		boolQuery.AddMust(queryTimeRange)
-	}
+// DocIDs 返回索引中全部文档的 id，供 search/indexer 的一致性巡检按 id 比对
+func DocIDs() ([]string, error) {
+	return engine.DocIDs()
+}
 
-	searchRequest := bleve.NewSearchRequest(boolQuery)
-	searchRequest.SortBy([]string{"createTime"})
-	searchRequest.Fields = []string{"did", "userId", "title", "content", "createTime"}
-	// 设置分页参数
-	searchRequest.From = (page - 1) * limit
-	searchRequest.Size = limit
+// SearchPage 按结构化条件分页查询话题，SortBy 为空时默认按发布时间倒序，
+// 指定 SortByRelevance 时按匹配得分排序
+func SearchPage(query SearchQuery, page, limit int) (docs []TopicDocument, paging *sqls.Paging, err error) {
+	paging = &sqls.Paging{Page: page, Limit: limit}
 
-	results, err := index.Search(searchRequest)
+	results, err := engine.Search(buildSearchRequest(query, page, limit))
 	if err != nil {
-		slog.Error("搜索失败:", slog.Any("err", err))
+		return nil, paging, err
 	}
 
 	for _, hit := range results.Hits {
-		var doc TopicDocument
+		docs = append(docs, docFromHit(hit))
+	}
 
-		doc.Type = strings.Split(hit.ID, "-")[0]
+	return
+}
 
-		if title, ok := hit.Fields["title"].(string); ok {
-			doc.Title = title
-		}
-		if content, ok := hit.Fields["content"].(string); ok {
-			doc.Content = content
-		}
+// docFromHit 把底层引擎返回的命中结果转换为 TopicDocument
+func docFromHit(hit *search.DocumentMatch) TopicDocument {
+	var doc TopicDocument
 
-		if userId, ok := hit.Fields["userId"].(float64); ok {
-			doc.UserId = userId
+	if title, ok := hit.Fields["title"].(string); ok {
+		doc.Title = title
+	}
+	if content, ok := hit.Fields["content"].(string); ok {
+		doc.Content = content
+	}
+	if userId, ok := hit.Fields["userId"].(float64); ok {
+		doc.UserId = int64(userId)
+	}
+	if id, ok := hit.Fields["id"].(float64); ok {
+		doc.Id = int64(id)
+	}
+	if nodeId, ok := hit.Fields["nodeId"].(float64); ok {
+		doc.NodeId = int64(nodeId)
+	}
+	if recommend, ok := hit.Fields["recommend"].(bool); ok {
+		doc.Recommend = recommend
+	}
+	if status, ok := hit.Fields["status"].(float64); ok {
+		doc.Status = int(status)
+	}
+	if createTime, ok := hit.Fields["createTime"].(float64); ok {
+		doc.CreateTime = int64(createTime)
+	}
+	if tags, ok := hit.Fields["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if tagStr, ok := tag.(string); ok {
+				doc.Tags = append(doc.Tags, tagStr)
+			}
 		}
+	} else if tag, ok := hit.Fields["tags"].(string); ok {
+		doc.Tags = []string{tag}
+	}
+	if len(hit.Fragments) > 0 {
+		doc.Highlights = hit.Fragments
+	}
 
-		if did, ok := hit.Fields["did"].(float64); ok {
-			doc.Id = did
-		}
+	return doc
+}
 
-		if createTime, ok := hit.Fields["createTime"].(float64); ok {
-			doc.CreateTime = createTime
-		}
+// SearchAdvanced 使用 bleve 的 query string DSL 进行检索，支持字段限定、布尔、
+// 范围等语法（如 `title:golang +tags:web -status:1 createTime:>1700000000`），
+// 供管理后台等需要精细控制查询的场景使用
+func SearchAdvanced(queryString string, page, limit int) (docs []TopicDocument, paging *sqls.Paging, err error) {
+	paging = &sqls.Paging{Page: page, Limit: limit}
+
+	searchRequest := bleve.NewSearchRequest(bleve.NewQueryStringQuery(queryString))
+	searchRequest.SortBy([]string{"-createTime"})
+	searchRequest.Fields = []string{"id", "nodeId", "userId", "title", "content", "tags", "recommend", "status", "createTime"}
+	searchRequest.Highlight = highlightRequest()
+	searchRequest.From = (page - 1) * limit
+	searchRequest.Size = limit
 
-		docs = append(docs, doc)
+	results, err := engine.Search(searchRequest)
+	if err != nil {
+		return nil, paging, err
+	}
+
+	for _, hit := range results.Hits {
+		docs = append(docs, docFromHit(hit))
 	}
 
 	return
 }
 
 func updateData(docID string, newData interface{}) error {
-	if err := index.Delete(docID); err != nil {
+	if err := engine.Delete(docID); err != nil {
 		slog.Error("删除索引失败～：", slog.Any("err", err))
 		return err
 	}
 
 	if newData != nil {
-		if err := index.Index(docID, newData); err != nil {
+		if err := engine.Index(docID, newData); err != nil {
 			slog.Error("重建索引失败～：", slog.Any("err", err))
 			return err
 		}